@@ -0,0 +1,113 @@
+// Command api runs the register-form HTTP API: registration, login,
+// email verification, and password reset.
+package main
+
+import (
+	"database/sql"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	_ "github.com/go-sql-driver/mysql"
+	"github.com/joho/godotenv"
+	"github.com/rs/zerolog"
+	zlog "github.com/rs/zerolog/log"
+
+	"github.com/lispa/register-form/pkg/config"
+	"github.com/lispa/register-form/pkg/httpapi"
+	"github.com/lispa/register-form/pkg/mail"
+	"github.com/lispa/register-form/pkg/migrate"
+	"github.com/lispa/register-form/pkg/users"
+)
+
+const tokenCleanupInterval = 1 * time.Hour
+
+func main() {
+	configPath := flag.String("config", "./config.toml", "path to the TOML config file")
+	genConfig := flag.Bool("gen-config", false, "write a default config file to -config and exit")
+	migrateOnly := flag.Bool("migrate-only", false, "apply pending migrations and exit")
+	flag.Parse()
+
+	if *genConfig {
+		if err := config.WriteDefault(*configPath); err != nil {
+			fmt.Fprintln(os.Stderr, "gen-config:", err)
+			os.Exit(1)
+		}
+		fmt.Println("wrote default config to", *configPath)
+		os.Exit(0)
+	}
+
+	// Load .env from project root (two levels above cmd/api) before reading
+	// config, since env vars are the config's override layer.
+	envPath := filepath.Join("..", "..", ".env")
+	envLoaded := godotenv.Load(envPath) == nil
+
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		zlog.Fatal().Err(err).Str("path", *configPath).Msg("failed to load config")
+	}
+	if cfg.JWT.Secret == "" {
+		zlog.Fatal().Msg("jwt.secret must be set (config file or JWT_SECRET)")
+	}
+
+	setupLogger(cfg.Log.Format)
+	if !envLoaded {
+		zlog.Info().Str("path", envPath).Msg("no .env file found, using OS env vars")
+	}
+	zlog.Info().Interface("config", cfg.Redacted()).Msg("effective configuration")
+
+	dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?parseTime=true&charset=utf8mb4&loc=Local",
+		cfg.DB.User, cfg.DB.Pass, cfg.DB.Host, cfg.DB.Port, cfg.DB.Name,
+	)
+
+	db, err := sql.Open("mysql", dsn)
+	if err != nil {
+		zlog.Fatal().Err(err).Msg("failed to open database")
+	}
+	defer db.Close()
+
+	db.SetConnMaxLifetime(2 * time.Minute)
+	db.SetMaxOpenConns(10)
+	db.SetMaxIdleConns(10)
+
+	if err := db.Ping(); err != nil {
+		zlog.Fatal().Err(err).Msg("db ping failed")
+	}
+
+	if err := migrate.Run(db); err != nil {
+		zlog.Fatal().Err(err).Msg("failed to apply migrations")
+	}
+	if *migrateOnly {
+		zlog.Info().Msg("migrations applied")
+		return
+	}
+
+	go users.CleanupExpiredTokens(db, tokenCleanupInterval)
+
+	mailer := mail.NewSMTPSender(cfg.SMTP.Host, cfg.SMTP.Port, cfg.SMTP.User, cfg.SMTP.Pass, cfg.SMTP.From)
+	srv := httpapi.NewServer(cfg, db, mailer)
+
+	server := &http.Server{
+		Addr:              cfg.Server.Addr,
+		Handler:           srv.Handler(),
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+
+	zlog.Info().Str("addr", cfg.Server.Addr).Msg("API listening")
+	zlog.Fatal().Err(server.ListenAndServe()).Msg("server stopped")
+}
+
+// setupLogger points the global zerolog logger at stdout, writing raw JSON
+// when format is "json" and using a pretty console writer for any other
+// value (including the default "console").
+func setupLogger(format string) {
+	var output io.Writer = os.Stdout
+	if format != "json" {
+		output = zerolog.ConsoleWriter{Out: os.Stdout, TimeFormat: time.RFC3339}
+	}
+	zlog.Logger = zerolog.New(output).With().Timestamp().Logger()
+}
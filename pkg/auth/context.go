@@ -0,0 +1,60 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"strings"
+)
+
+type contextKey int
+
+const userContextKey contextKey = 0
+
+// User is the subset of claims attached to a request's context once its
+// bearer token has been verified.
+type User struct {
+	ID    int64
+	Email string
+}
+
+// WithUser returns a copy of ctx carrying the authenticated user.
+func WithUser(ctx context.Context, u User) context.Context {
+	return context.WithValue(ctx, userContextKey, u)
+}
+
+// UserFromContext returns the authenticated user injected by Middleware, if
+// any.
+func UserFromContext(ctx context.Context) (User, bool) {
+	u, ok := ctx.Value(userContextKey).(User)
+	return u, ok
+}
+
+// Middleware parses the "Authorization: Bearer <token>" header, verifies it
+// against secret, and injects the resulting User into the request context
+// before calling next. Requests with a missing or invalid token are
+// rejected with 401 and next is never called.
+func Middleware(secret []byte, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		header := r.Header.Get("Authorization")
+		tokenString, ok := strings.CutPrefix(header, "Bearer ")
+		if !ok || tokenString == "" {
+			unauthorized(w)
+			return
+		}
+
+		claims, err := ParseToken(secret, tokenString)
+		if err != nil {
+			unauthorized(w)
+			return
+		}
+
+		ctx := WithUser(r.Context(), User{ID: claims.UserID, Email: claims.Email})
+		next(w, r.WithContext(ctx))
+	}
+}
+
+func unauthorized(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(http.StatusUnauthorized)
+	_, _ = w.Write([]byte(`{"ok":false,"message":"unauthorized"}`))
+}
@@ -0,0 +1,62 @@
+// Package auth issues and validates the JWT session tokens used to
+// authenticate API requests after a successful login.
+package auth
+
+import (
+	"errors"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// ErrInvalidToken is returned by ParseToken for any token that fails to verify,
+// is malformed, or has expired. Callers should treat it as "unauthenticated"
+// rather than distinguishing the underlying cause.
+var ErrInvalidToken = errors.New("invalid or expired token")
+
+// Claims is the JWT payload issued for an authenticated session.
+type Claims struct {
+	UserID int64  `json:"user_id"`
+	Email  string `json:"email"`
+	jwt.RegisteredClaims
+}
+
+// IssueToken signs a new HS256 session token for the given user that expires
+// after ttl. It returns the encoded token along with its expiry time so
+// callers can surface expires_at without re-parsing the token.
+func IssueToken(secret []byte, userID int64, email string, ttl time.Duration) (string, time.Time, error) {
+	now := time.Now()
+	expiresAt := now.Add(ttl)
+
+	claims := Claims{
+		UserID: userID,
+		Email:  email,
+		RegisteredClaims: jwt.RegisteredClaims{
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	signed, err := token.SignedString(secret)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return signed, expiresAt, nil
+}
+
+// ParseToken verifies the signature and expiry of a session token and
+// returns its claims.
+func ParseToken(secret []byte, tokenString string) (*Claims, error) {
+	claims := &Claims{}
+	token, err := jwt.ParseWithClaims(tokenString, claims, func(t *jwt.Token) (interface{}, error) {
+		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, ErrInvalidToken
+		}
+		return secret, nil
+	})
+	if err != nil || !token.Valid {
+		return nil, ErrInvalidToken
+	}
+	return claims, nil
+}
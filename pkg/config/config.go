@@ -0,0 +1,148 @@
+// Package config loads the API's TOML configuration file, layering process
+// environment variables on top as overrides.
+package config
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// Config is the fully-resolved configuration for a single run of the API.
+type Config struct {
+	DB        DBConfig        `mapstructure:"db"`
+	Server    ServerConfig    `mapstructure:"server"`
+	SMTP      SMTPConfig      `mapstructure:"smtp"`
+	JWT       JWTConfig       `mapstructure:"jwt"`
+	RateLimit RateLimitConfig `mapstructure:"rate_limit"`
+	Log       LogConfig       `mapstructure:"log"`
+}
+
+type DBConfig struct {
+	User string `mapstructure:"user"`
+	Pass string `mapstructure:"pass"`
+	Host string `mapstructure:"host"`
+	Port string `mapstructure:"port"`
+	Name string `mapstructure:"name"`
+}
+
+type ServerConfig struct {
+	Addr    string `mapstructure:"addr"`
+	BaseURL string `mapstructure:"base_url"`
+}
+
+type SMTPConfig struct {
+	Host string `mapstructure:"host"`
+	Port string `mapstructure:"port"`
+	User string `mapstructure:"user"`
+	Pass string `mapstructure:"pass"`
+	From string `mapstructure:"from"`
+}
+
+type JWTConfig struct {
+	Secret   string        `mapstructure:"secret"`
+	TokenTTL time.Duration `mapstructure:"token_ttl"`
+}
+
+type RateLimitConfig struct {
+	IPPerMinute  int `mapstructure:"ip_per_minute"`
+	EmailPerHour int `mapstructure:"email_per_hour"`
+}
+
+type LogConfig struct {
+	// Format is "console" for human-readable dev output or "json" for
+	// structured production logs.
+	Format string `mapstructure:"format"`
+}
+
+// Load reads the TOML file at path, if present, and unmarshals it into a
+// Config. Missing sections and a missing file entirely both fall back to
+// defaults. Environment variables listed in bindEnvOverrides always take
+// precedence over both the file and the defaults.
+func Load(path string) (*Config, error) {
+	v := viper.New()
+	v.SetConfigFile(path)
+	v.SetConfigType("toml")
+
+	setDefaults(v)
+	bindEnvOverrides(v)
+
+	if err := v.ReadInConfig(); err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("read config %s: %w", path, err)
+	}
+
+	var cfg Config
+	if err := v.Unmarshal(&cfg); err != nil {
+		return nil, fmt.Errorf("parse config: %w", err)
+	}
+	return &cfg, nil
+}
+
+func setDefaults(v *viper.Viper) {
+	v.SetDefault("db.user", "root")
+	v.SetDefault("db.pass", "")
+	v.SetDefault("db.host", "127.0.0.1")
+	v.SetDefault("db.port", "3306")
+	v.SetDefault("db.name", "userregistr")
+
+	v.SetDefault("server.addr", ":8080")
+	v.SetDefault("server.base_url", "http://localhost:8080")
+
+	v.SetDefault("smtp.host", "localhost")
+	v.SetDefault("smtp.port", "587")
+	v.SetDefault("smtp.user", "")
+	v.SetDefault("smtp.pass", "")
+	v.SetDefault("smtp.from", "no-reply@localhost")
+
+	v.SetDefault("jwt.secret", "")
+	v.SetDefault("jwt.token_ttl", "24h")
+
+	v.SetDefault("rate_limit.ip_per_minute", 5)
+	v.SetDefault("rate_limit.email_per_hour", 10)
+
+	v.SetDefault("log.format", "console")
+}
+
+// bindEnvOverrides keeps the env vars the API has always honored working as
+// overrides of whatever the config file or defaults say.
+func bindEnvOverrides(v *viper.Viper) {
+	_ = v.BindEnv("db.user", "DB_USER")
+	_ = v.BindEnv("db.pass", "DB_PASS")
+	_ = v.BindEnv("db.host", "DB_HOST")
+	_ = v.BindEnv("db.port", "DB_PORT")
+	_ = v.BindEnv("db.name", "DB_NAME")
+
+	_ = v.BindEnv("server.addr", "SERVER_ADDR")
+	_ = v.BindEnv("server.base_url", "APP_BASE_URL")
+
+	_ = v.BindEnv("smtp.host", "SMTP_HOST")
+	_ = v.BindEnv("smtp.port", "SMTP_PORT")
+	_ = v.BindEnv("smtp.user", "SMTP_USER")
+	_ = v.BindEnv("smtp.pass", "SMTP_PASS")
+	_ = v.BindEnv("smtp.from", "MAIL_FROM")
+
+	_ = v.BindEnv("jwt.secret", "JWT_SECRET")
+
+	_ = v.BindEnv("rate_limit.ip_per_minute", "RATE_IP_PER_MIN")
+	_ = v.BindEnv("rate_limit.email_per_hour", "RATE_EMAIL_PER_HOUR")
+
+	_ = v.BindEnv("log.format", "LOG_FORMAT")
+}
+
+// Redacted returns a copy of cfg with secret fields masked, safe to log.
+func (c Config) Redacted() Config {
+	redacted := c
+	redacted.DB.Pass = mask(c.DB.Pass)
+	redacted.SMTP.Pass = mask(c.SMTP.Pass)
+	redacted.JWT.Secret = mask(c.JWT.Secret)
+	return redacted
+}
+
+func mask(secret string) string {
+	if secret == "" {
+		return ""
+	}
+	return "********"
+}
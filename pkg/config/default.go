@@ -0,0 +1,60 @@
+package config
+
+import (
+	"fmt"
+	"os"
+)
+
+// defaultTOML is written to disk by WriteDefault. Every value matches the
+// defaults set in Load, spelled out explicitly so operators can see what
+// they're overriding.
+const defaultTOML = `# Configuration for the register-form API.
+# Generated with -gen-config. Edit the values below, or leave a section
+# commented out to fall back to its default.
+#
+# Every value here can also be set (or overridden) via an environment
+# variable, e.g. DB_USER, JWT_SECRET, SMTP_PASS — see bindEnvOverrides in
+# pkg/config/config.go for the full list.
+
+[db]
+user = "root"
+pass = ""
+host = "127.0.0.1"
+port = "3306"
+name = "userregistr"
+
+[server]
+addr = ":8080"
+base_url = "http://localhost:8080"
+
+[smtp]
+host = "localhost"
+port = "587"
+user = ""
+pass = ""
+from = "no-reply@localhost"
+
+[jwt]
+# Required: must be set here or via JWT_SECRET before the server will start.
+secret = ""
+token_ttl = "24h"
+
+[rate_limit]
+ip_per_minute = 5
+email_per_hour = 10
+
+[log]
+# "console" for human-readable dev output, "json" for structured prod logs.
+format = "console"
+`
+
+// WriteDefault writes the commented default configuration to path. It
+// refuses to overwrite an existing file.
+func WriteDefault(path string) error {
+	if _, err := os.Stat(path); err == nil {
+		return fmt.Errorf("%s already exists", path)
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+	return os.WriteFile(path, []byte(defaultTOML), 0o644)
+}
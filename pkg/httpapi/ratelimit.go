@@ -0,0 +1,48 @@
+package httpapi
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lispa/register-form/pkg/ratelimit"
+)
+
+// withRateLimit gates next behind a per-IP token bucket, responding 429
+// with a Retry-After header once the caller's bucket is empty.
+func withRateLimit(limiter *ratelimit.Limiter, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodOptions {
+			next(w, r)
+			return
+		}
+		if allowed, retryAfter := limiter.Allow(clientIP(r)); !allowed {
+			writeRateLimited(w, retryAfter)
+			return
+		}
+		next(w, r)
+	}
+}
+
+func writeRateLimited(w http.ResponseWriter, retryAfter time.Duration) {
+	w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+	writeJSON(w, http.StatusTooManyRequests, APIResponse{OK: false, Message: "rate limited"})
+}
+
+// clientIP returns the caller's address, preferring the first hop in
+// X-Forwarded-For (set by a reverse proxy) over the raw RemoteAddr.
+func clientIP(r *http.Request) string {
+	if fwd := r.Header.Get("X-Forwarded-For"); fwd != "" {
+		if ip := strings.TrimSpace(strings.Split(fwd, ",")[0]); ip != "" {
+			return ip
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -0,0 +1,404 @@
+// Package httpapi wires the register/login/verify/password-reset HTTP
+// handlers together behind CORS and rate-limiting middleware.
+package httpapi
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	zlog "github.com/rs/zerolog/log"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/lispa/register-form/pkg/auth"
+	"github.com/lispa/register-form/pkg/config"
+	"github.com/lispa/register-form/pkg/mail"
+	"github.com/lispa/register-form/pkg/ratelimit"
+	"github.com/lispa/register-form/pkg/users"
+)
+
+const (
+	verificationTokenTTL = 24 * time.Hour
+	resetTokenTTL        = 1 * time.Hour
+)
+
+// unknownPasswordHash is compared against on every failed lookup-by-email so
+// that a login against a non-existent account costs roughly the same time
+// as one against a real account with the wrong password, making the two
+// indistinguishable to a timing-based enumeration attack.
+const unknownPasswordHash = "$2a$10$N3pEHGNI3IUlnhSBcEVFMuuP2Qh8IngR3cccve2fn0fIEjVikXQNO"
+
+var emailRe = regexp.MustCompile(`^[^\s@]+@[^\s@]+\.[^\s@]+$`)
+
+// Server holds the dependencies shared by all handlers.
+type Server struct {
+	db           *sql.DB
+	mailer       mail.Sender
+	cfg          *config.Config
+	ipLimiter    *ratelimit.Limiter
+	emailLimiter *ratelimit.Limiter
+}
+
+// NewServer builds a Server ready to serve the API's routes.
+func NewServer(cfg *config.Config, db *sql.DB, mailer mail.Sender) *Server {
+	return &Server{
+		db:           db,
+		mailer:       mailer,
+		cfg:          cfg,
+		ipLimiter:    ratelimit.New(cfg.RateLimit.IPPerMinute, time.Minute),
+		emailLimiter: ratelimit.New(cfg.RateLimit.EmailPerHour, time.Hour),
+	}
+}
+
+// Handler returns the fully configured mux for this Server, wrapped in
+// request logging.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/api/register", withCORS(withRateLimit(s.ipLimiter, s.handleRegister)))
+	mux.HandleFunc("/api/login", withCORS(withRateLimit(s.ipLimiter, s.handleLogin)))
+	mux.HandleFunc("/api/me", withCORS(auth.Middleware([]byte(s.cfg.JWT.Secret), s.handleMe)))
+	mux.HandleFunc("/api/verify", withCORS(s.handleVerify))
+	mux.HandleFunc("/api/password/forgot", withCORS(s.handleForgotPassword))
+	mux.HandleFunc("/api/password/reset", withCORS(s.handleResetPassword))
+
+	return logRequests(mux)
+}
+
+func (s *Server) handleRegister(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodOptions {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	if r.Method != http.MethodPost {
+		writeJSON(w, http.StatusMethodNotAllowed, APIResponse{OK: false, Message: "method not allowed"})
+		return
+	}
+
+	var req RegisterRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, APIResponse{OK: false, Message: "invalid json"})
+		return
+	}
+
+	req.FirstName = strings.TrimSpace(req.FirstName)
+	req.LastName = strings.TrimSpace(req.LastName)
+	req.Email = strings.TrimSpace(strings.ToLower(req.Email))
+
+	// Validation
+	if len(req.FirstName) < 2 {
+		writeJSON(w, http.StatusBadRequest, APIResponse{OK: false, Message: "first_name too short"})
+		return
+	}
+	if len(req.LastName) < 2 {
+		writeJSON(w, http.StatusBadRequest, APIResponse{OK: false, Message: "last_name too short"})
+		return
+	}
+	if !emailRe.MatchString(req.Email) {
+		writeJSON(w, http.StatusBadRequest, APIResponse{OK: false, Message: "invalid email"})
+		return
+	}
+	if !isStrongPassword(req.Password) {
+		writeJSON(w, http.StatusBadRequest, APIResponse{OK: false, Message: "weak password (8+, upper, lower, digit)"})
+		return
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, APIResponse{OK: false, Message: "failed to hash password"})
+		return
+	}
+
+	userID, err := users.Insert(s.db, req.FirstName, req.LastName, req.Email, string(hash))
+	if err != nil {
+		if errors.Is(err, users.ErrEmailExists) {
+			writeJSON(w, http.StatusConflict, APIResponse{OK: false, Message: "email already exists"})
+			return
+		}
+		zlog.Error().Err(err).Msg("insert error")
+		writeJSON(w, http.StatusInternalServerError, APIResponse{OK: false, Message: "internal error"})
+		return
+	}
+
+	go s.sendVerificationEmail(userID, req.Email)
+
+	writeJSON(w, http.StatusCreated, APIResponse{OK: true, Message: "registered"})
+}
+
+func (s *Server) handleLogin(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodOptions {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	if r.Method != http.MethodPost {
+		writeJSON(w, http.StatusMethodNotAllowed, APIResponse{OK: false, Message: "method not allowed"})
+		return
+	}
+
+	var req LoginRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, APIResponse{OK: false, Message: "invalid json"})
+		return
+	}
+	req.Email = strings.TrimSpace(strings.ToLower(req.Email))
+
+	if blocked, retryAfter := s.emailLimiter.Blocked(req.Email); blocked {
+		writeRateLimited(w, retryAfter)
+		return
+	}
+
+	user, err := users.GetByEmail(s.db, req.Email)
+	if err != nil && !errors.Is(err, users.ErrNotFound) {
+		zlog.Error().Err(err).Msg("lookup error")
+		writeJSON(w, http.StatusInternalServerError, APIResponse{OK: false, Message: "internal error"})
+		return
+	}
+
+	// Always run a bcrypt comparison, even for an unknown email, so the
+	// response takes the same time either way and the existence of an
+	// account can't be inferred from latency.
+	hash := unknownPasswordHash
+	if user != nil {
+		hash = user.PasswordHash
+	}
+	compareErr := bcrypt.CompareHashAndPassword([]byte(hash), []byte(req.Password))
+	if user == nil || compareErr != nil {
+		s.emailLimiter.Allow(req.Email)
+		writeJSON(w, http.StatusUnauthorized, APIResponse{OK: false, Message: "invalid email or password"})
+		return
+	}
+
+	token, expiresAt, err := auth.IssueToken([]byte(s.cfg.JWT.Secret), user.ID, user.Email, s.cfg.JWT.TokenTTL)
+	if err != nil {
+		zlog.Error().Err(err).Msg("token issue error")
+		writeJSON(w, http.StatusInternalServerError, APIResponse{OK: false, Message: "internal error"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, LoginResponse{
+		APIResponse: APIResponse{OK: true, Message: "logged in"},
+		Token:       token,
+		ExpiresAt:   expiresAt,
+	})
+}
+
+func (s *Server) handleMe(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodOptions {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	if r.Method != http.MethodGet {
+		writeJSON(w, http.StatusMethodNotAllowed, APIResponse{OK: false, Message: "method not allowed"})
+		return
+	}
+
+	u, ok := auth.UserFromContext(r.Context())
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, APIResponse{OK: false, Message: "unauthorized"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, MeResponse{
+		APIResponse: APIResponse{OK: true, Message: "ok"},
+		ID:          u.ID,
+		Email:       u.Email,
+	})
+}
+
+func (s *Server) handleVerify(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodOptions {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	if r.Method != http.MethodGet {
+		writeJSON(w, http.StatusMethodNotAllowed, APIResponse{OK: false, Message: "method not allowed"})
+		return
+	}
+
+	token := r.URL.Query().Get("token")
+	userID, err := users.ConsumeToken(s.db, token, users.PurposeVerify)
+	if err != nil {
+		if errors.Is(err, users.ErrTokenInvalid) {
+			writeJSON(w, http.StatusBadRequest, APIResponse{OK: false, Message: "invalid or expired token"})
+			return
+		}
+		zlog.Error().Err(err).Msg("verify error")
+		writeJSON(w, http.StatusInternalServerError, APIResponse{OK: false, Message: "internal error"})
+		return
+	}
+
+	if err := users.MarkVerified(s.db, userID); err != nil {
+		zlog.Error().Err(err).Msg("mark verified error")
+		writeJSON(w, http.StatusInternalServerError, APIResponse{OK: false, Message: "internal error"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, APIResponse{OK: true, Message: "email verified"})
+}
+
+func (s *Server) handleForgotPassword(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodOptions {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	if r.Method != http.MethodPost {
+		writeJSON(w, http.StatusMethodNotAllowed, APIResponse{OK: false, Message: "method not allowed"})
+		return
+	}
+
+	var req ForgotPasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, APIResponse{OK: false, Message: "invalid json"})
+		return
+	}
+	req.Email = strings.TrimSpace(strings.ToLower(req.Email))
+
+	// Always respond 200 regardless of whether the email exists, so the
+	// response can't be used to enumerate registered accounts.
+	user, err := users.GetByEmail(s.db, req.Email)
+	if err == nil {
+		go s.sendResetEmail(user.ID, user.Email)
+	} else if !errors.Is(err, users.ErrNotFound) {
+		zlog.Error().Err(err).Msg("lookup error")
+	}
+
+	writeJSON(w, http.StatusOK, APIResponse{OK: true, Message: "if that email exists, a reset link has been sent"})
+}
+
+func (s *Server) handleResetPassword(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodOptions {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	if r.Method != http.MethodPost {
+		writeJSON(w, http.StatusMethodNotAllowed, APIResponse{OK: false, Message: "method not allowed"})
+		return
+	}
+
+	var req ResetPasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, APIResponse{OK: false, Message: "invalid json"})
+		return
+	}
+
+	if !isStrongPassword(req.Password) {
+		writeJSON(w, http.StatusBadRequest, APIResponse{OK: false, Message: "weak password (8+, upper, lower, digit)"})
+		return
+	}
+
+	userID, err := users.ConsumeToken(s.db, req.Token, users.PurposeReset)
+	if err != nil {
+		if errors.Is(err, users.ErrTokenInvalid) {
+			writeJSON(w, http.StatusBadRequest, APIResponse{OK: false, Message: "invalid or expired token"})
+			return
+		}
+		zlog.Error().Err(err).Msg("reset token error")
+		writeJSON(w, http.StatusInternalServerError, APIResponse{OK: false, Message: "internal error"})
+		return
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, APIResponse{OK: false, Message: "failed to hash password"})
+		return
+	}
+
+	if err := users.UpdatePassword(s.db, userID, string(hash)); err != nil {
+		zlog.Error().Err(err).Msg("password update error")
+		writeJSON(w, http.StatusInternalServerError, APIResponse{OK: false, Message: "internal error"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, APIResponse{OK: true, Message: "password updated"})
+}
+
+// sendVerificationEmail issues a verification token for userID and emails
+// the confirmation link. Callers run this in its own goroutine so account
+// creation doesn't block on SMTP; failures are only logged.
+func (s *Server) sendVerificationEmail(userID int64, email string) {
+	token, err := users.CreateToken(s.db, userID, users.PurposeVerify, verificationTokenTTL)
+	if err != nil {
+		zlog.Error().Err(err).Msg("create verification token error")
+		return
+	}
+	link := fmt.Sprintf("%s/api/verify?token=%s", s.cfg.Server.BaseURL, token)
+	subject, htmlBody, err := mail.VerificationEmail(link)
+	if err != nil {
+		zlog.Error().Err(err).Msg("render verification email error")
+		return
+	}
+	if err := s.mailer.Send(email, subject, htmlBody); err != nil {
+		zlog.Error().Err(err).Msg("send verification email error")
+	}
+}
+
+// sendResetEmail issues a password reset token for userID and emails the
+// reset link. Callers run this in its own goroutine: the handler must
+// return a uniform response regardless of whether the account exists, and
+// waiting on a token INSERT plus a blocking SMTP send here would leak
+// exactly that via response timing.
+func (s *Server) sendResetEmail(userID int64, email string) {
+	token, err := users.CreateToken(s.db, userID, users.PurposeReset, resetTokenTTL)
+	if err != nil {
+		zlog.Error().Err(err).Msg("create reset token error")
+		return
+	}
+	link := fmt.Sprintf("%s/reset-password?token=%s", s.cfg.Server.BaseURL, token)
+	subject, htmlBody, err := mail.ResetEmail(link)
+	if err != nil {
+		zlog.Error().Err(err).Msg("render reset email error")
+		return
+	}
+	if err := s.mailer.Send(email, subject, htmlBody); err != nil {
+		zlog.Error().Err(err).Msg("send reset email error")
+	}
+}
+
+// Password: at least 8 chars, 1 uppercase, 1 lowercase, 1 digit
+func isStrongPassword(p string) bool {
+	if len(p) < 8 {
+		return false
+	}
+	hasUpper := regexp.MustCompile(`[A-Z]`).MatchString(p)
+	hasLower := regexp.MustCompile(`[a-z]`).MatchString(p)
+	hasDigit := regexp.MustCompile(`\d`).MatchString(p)
+	return hasUpper && hasLower && hasDigit
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func withCORS(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+		if r.Method == http.MethodOptions {
+			// Answer the preflight here so it never reaches auth.Middleware,
+			// which would otherwise 401 it for lacking an Authorization header.
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		next(w, r)
+	}
+}
+
+func logRequests(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		next.ServeHTTP(w, r)
+		zlog.Info().
+			Str("method", r.Method).
+			Str("path", r.URL.Path).
+			Dur("duration", time.Since(start)).
+			Msg("request")
+	})
+}
@@ -0,0 +1,43 @@
+package httpapi
+
+import "time"
+
+type RegisterRequest struct {
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+	Email     string `json:"email"`
+	Password  string `json:"password"`
+}
+
+type LoginRequest struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email"`
+}
+
+type ResetPasswordRequest struct {
+	Token    string `json:"token"`
+	Password string `json:"password"`
+}
+
+type APIResponse struct {
+	OK      bool   `json:"ok"`
+	Message string `json:"message"`
+}
+
+// LoginResponse is an APIResponse augmented with the issued session token.
+type LoginResponse struct {
+	APIResponse
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// MeResponse reports the identity of the currently authenticated user.
+type MeResponse struct {
+	APIResponse
+	ID    int64  `json:"id"`
+	Email string `json:"email"`
+}
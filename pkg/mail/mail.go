@@ -0,0 +1,69 @@
+// Package mail renders and sends the transactional emails (verification,
+// password reset) triggered by the registration and auth flows.
+package mail
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"html/template"
+	"net/smtp"
+)
+
+//go:embed templates/*.html
+var templatesFS embed.FS
+
+var templates = template.Must(template.ParseFS(templatesFS, "templates/*.html"))
+
+// Sender delivers a rendered HTML email. It is an interface so handlers can
+// be tested against a fake without talking to a real SMTP server.
+type Sender interface {
+	Send(to, subject, htmlBody string) error
+}
+
+// SMTPSender sends mail through an SMTP relay authenticated with PLAIN auth.
+type SMTPSender struct {
+	Host string
+	Port string
+	User string
+	Pass string
+	From string
+}
+
+// NewSMTPSender builds a Sender configured from the given SMTP credentials.
+func NewSMTPSender(host, port, user, pass, from string) *SMTPSender {
+	return &SMTPSender{Host: host, Port: port, User: user, Pass: pass, From: from}
+}
+
+// Send delivers htmlBody to to as a UTF-8 HTML email with the given subject.
+func (s *SMTPSender) Send(to, subject, htmlBody string) error {
+	addr := fmt.Sprintf("%s:%s", s.Host, s.Port)
+	auth := smtp.PlainAuth("", s.User, s.Pass, s.Host)
+
+	msg := fmt.Sprintf(
+		"From: %s\r\nTo: %s\r\nSubject: %s\r\nMIME-Version: 1.0\r\nContent-Type: text/html; charset=\"UTF-8\"\r\n\r\n%s",
+		s.From, to, subject, htmlBody,
+	)
+
+	return smtp.SendMail(addr, auth, s.From, []string{to}, []byte(msg))
+}
+
+// VerificationEmail renders the "confirm your email" message for the given
+// verification link.
+func VerificationEmail(link string) (subject, htmlBody string, err error) {
+	return render("verify.html", "Confirm your email", link)
+}
+
+// ResetEmail renders the "reset your password" message for the given reset
+// link.
+func ResetEmail(link string) (subject, htmlBody string, err error) {
+	return render("reset.html", "Reset your password", link)
+}
+
+func render(name, subject, link string) (string, string, error) {
+	var buf bytes.Buffer
+	if err := templates.ExecuteTemplate(&buf, name, struct{ Link string }{Link: link}); err != nil {
+		return "", "", err
+	}
+	return subject, buf.String(), nil
+}
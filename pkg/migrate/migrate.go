@@ -0,0 +1,152 @@
+// Package migrate applies the embedded numbered SQL migrations in
+// migrations/ to bring a fresh or existing database up to the schema the
+// rest of the API expects.
+//
+// Migrations are forward-only: each *.up.sql file must contain exactly one
+// statement, since the mysql driver connection is not opened with
+// multiStatements and MySQL implicitly commits before/after every DDL
+// statement, so there is no way to make a multi-statement file atomic
+// anyway. There are no .down.sql files; rolling back a bad migration is a
+// manual operation against the target database.
+package migrate
+
+import (
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//go:embed migrations/*.up.sql
+var migrationsFS embed.FS
+
+type migration struct {
+	version int
+	name    string
+	up      string
+}
+
+// Run applies any migrations not yet recorded in schema_migrations, in
+// version order. It fails fast on the first error, leaving the database at
+// the last successfully applied version.
+func Run(db *sql.DB) error {
+	if err := ensureVersionTable(db); err != nil {
+		return fmt.Errorf("ensure schema_migrations: %w", err)
+	}
+
+	applied, err := appliedVersions(db)
+	if err != nil {
+		return fmt.Errorf("read applied versions: %w", err)
+	}
+
+	migrations, err := loadMigrations()
+	if err != nil {
+		return fmt.Errorf("load migrations: %w", err)
+	}
+
+	for _, m := range migrations {
+		if applied[m.version] {
+			continue
+		}
+		if err := apply(db, m); err != nil {
+			return fmt.Errorf("migration %04d_%s: %w", m.version, m.name, err)
+		}
+	}
+	return nil
+}
+
+func ensureVersionTable(db *sql.DB) error {
+	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+		version    INT PRIMARY KEY,
+		applied_at TIMESTAMP NOT NULL
+	)`)
+	return err
+}
+
+func appliedVersions(db *sql.DB) (map[int]bool, error) {
+	rows, err := db.Query(`SELECT version FROM schema_migrations`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := make(map[int]bool)
+	for rows.Next() {
+		var v int
+		if err := rows.Scan(&v); err != nil {
+			return nil, err
+		}
+		applied[v] = true
+	}
+	return applied, rows.Err()
+}
+
+// apply runs a single migration's statement and records it in
+// schema_migrations inside one transaction. Note that this only makes the
+// bookkeeping insert atomic with the statement from Go's point of view: DDL
+// (CREATE/ALTER) implicitly commits in MySQL regardless of the surrounding
+// transaction, so a crash between the two tx.Exec calls can still leave the
+// statement applied without a corresponding schema_migrations row. Keeping
+// each migration file to exactly one statement limits the damage to "rerun
+// this migration's effect by hand," never a half-applied file.
+func apply(db *sql.DB, m migration) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(m.up); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(
+		`INSERT INTO schema_migrations (version, applied_at) VALUES (?, ?)`,
+		m.version, time.Now(),
+	); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// loadMigrations reads every embedded *.up.sql file and returns them sorted
+// by version, parsed from the "NNNN_name.up.sql" filename.
+func loadMigrations() ([]migration, error) {
+	entries, err := fs.Glob(migrationsFS, "migrations/*.up.sql")
+	if err != nil {
+		return nil, err
+	}
+
+	migrations := make([]migration, 0, len(entries))
+	for _, path := range entries {
+		base := strings.TrimSuffix(strings.TrimPrefix(path, "migrations/"), ".up.sql")
+		version, name, err := parseFilename(base)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+
+		contents, err := migrationsFS.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		migrations = append(migrations, migration{version: version, name: name, up: string(contents)})
+	}
+
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].version < migrations[j].version })
+	return migrations, nil
+}
+
+func parseFilename(base string) (int, string, error) {
+	parts := strings.SplitN(base, "_", 2)
+	if len(parts) != 2 {
+		return 0, "", fmt.Errorf("expected NNNN_name, got %q", base)
+	}
+	version, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, "", fmt.Errorf("invalid version prefix %q: %w", parts[0], err)
+	}
+	return version, parts[1], nil
+}
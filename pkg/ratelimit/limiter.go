@@ -0,0 +1,109 @@
+// Package ratelimit implements a small in-memory token-bucket limiter used
+// to slow brute-force and credential-stuffing attempts against the
+// register/login endpoints.
+package ratelimit
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// Limiter grants up to limit events per window for a given key, refilling
+// gradually (a standard token bucket) rather than resetting in one step at
+// window boundaries. Buckets are stored in a sync.Map and idle ones are
+// evicted periodically so memory usage stays bounded.
+type Limiter struct {
+	limit   float64
+	refill  float64  // tokens added per second
+	buckets sync.Map // key -> *bucket
+}
+
+type bucket struct {
+	mu         sync.Mutex
+	tokens     float64
+	lastRefill time.Time
+}
+
+// New returns a Limiter allowing limit events per window, per key. It starts
+// a background goroutine that evicts buckets which have been idle for a
+// full window; callers do not need to stop it explicitly, as it is meant to
+// live for the lifetime of the process.
+func New(limit int, window time.Duration) *Limiter {
+	l := &Limiter{
+		limit:  float64(limit),
+		refill: float64(limit) / window.Seconds(),
+	}
+	go l.evictIdle(window)
+	return l
+}
+
+// Allow reports whether an event for key is permitted right now. If it is,
+// one token is consumed. If it isn't, the returned duration is how long the
+// caller should wait before retrying.
+func (l *Limiter) Allow(key string) (bool, time.Duration) {
+	b := l.bucketFor(key)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	l.refillLocked(b)
+
+	if b.tokens < 1 {
+		wait := time.Duration((1 - b.tokens) / l.refill * float64(time.Second))
+		return false, wait
+	}
+	b.tokens--
+	return true, 0
+}
+
+// Blocked reports whether key is currently out of tokens, without consuming
+// one, along with how long until a token is available if so. It is used to
+// gate an action before attempting it (e.g. refusing a login attempt
+// outright once an email has too many recent failures).
+func (l *Limiter) Blocked(key string) (bool, time.Duration) {
+	b := l.bucketFor(key)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	l.refillLocked(b)
+	if b.tokens >= 1 {
+		return false, 0
+	}
+	wait := time.Duration((1 - b.tokens) / l.refill * float64(time.Second))
+	return true, wait
+}
+
+func (l *Limiter) bucketFor(key string) *bucket {
+	v, _ := l.buckets.LoadOrStore(key, &bucket{tokens: l.limit, lastRefill: time.Now()})
+	return v.(*bucket)
+}
+
+// refillLocked tops up b's tokens based on elapsed time. b.mu must be held.
+func (l *Limiter) refillLocked(b *bucket) {
+	now := time.Now()
+	elapsed := now.Sub(b.lastRefill).Seconds()
+	b.tokens = math.Min(l.limit, b.tokens+elapsed*l.refill)
+	b.lastRefill = now
+}
+
+// evictIdle periodically drops buckets that are back at full capacity,
+// i.e. have not been touched in at least a full window.
+func (l *Limiter) evictIdle(window time.Duration) {
+	ticker := time.NewTicker(window)
+	defer ticker.Stop()
+	for range ticker.C {
+		now := time.Now()
+		l.buckets.Range(func(k, v any) bool {
+			b := v.(*bucket)
+			b.mu.Lock()
+			idle := now.Sub(b.lastRefill) >= window
+			b.mu.Unlock()
+			if idle {
+				l.buckets.Delete(k)
+			}
+			return true
+		})
+	}
+}
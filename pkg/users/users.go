@@ -0,0 +1,151 @@
+// Package users owns the users and user_tokens tables: account lookup,
+// creation, and the verification/reset tokens issued against an account.
+package users
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	zlog "github.com/rs/zerolog/log"
+)
+
+// Token purposes stored alongside a user_tokens row.
+const (
+	PurposeVerify = "verify"
+	PurposeReset  = "reset"
+)
+
+var (
+	// ErrEmailExists is returned by Insert when the email is already taken.
+	ErrEmailExists = errors.New("email exists")
+	// ErrNotFound is returned when no user matches the lookup.
+	ErrNotFound = errors.New("user not found")
+	// ErrTokenInvalid is returned for a token that does not exist, was
+	// issued for a different purpose, or has expired.
+	ErrTokenInvalid = errors.New("token invalid or expired")
+)
+
+// User is a row from the users table.
+type User struct {
+	ID           int64
+	FirstName    string
+	LastName     string
+	Email        string
+	PasswordHash string
+	Verified     bool
+}
+
+// Insert creates a new, unverified user and returns its id.
+func Insert(db *sql.DB, firstName, lastName, email, passwordHash string) (int64, error) {
+	res, err := db.Exec(
+		`INSERT INTO users (first_name, last_name, email, password_hash, verified) VALUES (?, ?, ?, ?, false)`,
+		firstName, lastName, email, passwordHash,
+	)
+	if err != nil {
+		// MySQL duplicate key error code: 1062
+		if strings.Contains(err.Error(), "1062") {
+			return 0, ErrEmailExists
+		}
+		return 0, err
+	}
+	return res.LastInsertId()
+}
+
+// GetByEmail looks up a user by email, returning ErrNotFound if none exists.
+func GetByEmail(db *sql.DB, email string) (*User, error) {
+	var u User
+	err := db.QueryRow(
+		`SELECT id, first_name, last_name, email, password_hash, verified FROM users WHERE email = ?`,
+		email,
+	).Scan(&u.ID, &u.FirstName, &u.LastName, &u.Email, &u.PasswordHash, &u.Verified)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return &u, nil
+}
+
+// MarkVerified flips the verified flag for userID.
+func MarkVerified(db *sql.DB, userID int64) error {
+	_, err := db.Exec(`UPDATE users SET verified = true WHERE id = ?`, userID)
+	return err
+}
+
+// UpdatePassword rotates userID's stored password hash.
+func UpdatePassword(db *sql.DB, userID int64, passwordHash string) error {
+	_, err := db.Exec(`UPDATE users SET password_hash = ? WHERE id = ?`, passwordHash, userID)
+	return err
+}
+
+func generateToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// CreateToken issues a new random token for userID under purpose, valid for
+// ttl, and stores it in user_tokens.
+func CreateToken(db *sql.DB, userID int64, purpose string, ttl time.Duration) (string, error) {
+	token, err := generateToken()
+	if err != nil {
+		return "", err
+	}
+	_, err = db.Exec(
+		`INSERT INTO user_tokens (token, user_id, purpose, expires_at) VALUES (?, ?, ?, ?)`,
+		token, userID, purpose, time.Now().Add(ttl),
+	)
+	if err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// ConsumeToken looks up an unexpired token issued for purpose and deletes it
+// so it cannot be reused, returning the user it belongs to.
+func ConsumeToken(db *sql.DB, token, purpose string) (int64, error) {
+	if token == "" {
+		return 0, ErrTokenInvalid
+	}
+
+	var userID int64
+	var expiresAt time.Time
+	err := db.QueryRow(
+		`SELECT user_id, expires_at FROM user_tokens WHERE token = ? AND purpose = ?`,
+		token, purpose,
+	).Scan(&userID, &expiresAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, ErrTokenInvalid
+		}
+		return 0, err
+	}
+
+	if _, err := db.Exec(`DELETE FROM user_tokens WHERE token = ?`, token); err != nil {
+		return 0, err
+	}
+	if time.Now().After(expiresAt) {
+		return 0, ErrTokenInvalid
+	}
+	return userID, nil
+}
+
+// CleanupExpiredTokens periodically deletes expired user_tokens rows so the
+// table doesn't grow unbounded with unused verification/reset tokens. It
+// blocks forever and is meant to be run in its own goroutine.
+func CleanupExpiredTokens(db *sql.DB, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		if _, err := db.Exec(`DELETE FROM user_tokens WHERE expires_at < ?`, time.Now()); err != nil {
+			zlog.Error().Err(err).Msg("token cleanup error")
+		}
+	}
+}